@@ -0,0 +1,102 @@
+package htmlquery
+
+import (
+	"sync"
+
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// defaultCacheSize is the maximum number of compiled XPath expressions the
+// package-level cache holds before it resets, mirroring the capacity-reset
+// strategy antchfx/xpath itself uses for its internal regexp cache.
+const defaultCacheSize = 500
+
+var (
+	cacheMu   sync.RWMutex
+	cacheOn   = true
+	cacheSize = defaultCacheSize
+	exprCache = make(map[string]*xpath.Expr)
+)
+
+// SetCacheSize sets the maximum number of compiled XPath expressions kept
+// in the package-level cache. Once the cache reaches size, it is cleared
+// and starts filling again. A size <= 0 clears the cache and disables
+// caching until SetCacheSize is called again with a positive value.
+func SetCacheSize(size int) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheSize = size
+	exprCache = make(map[string]*xpath.Expr)
+	if size <= 0 {
+		cacheOn = false
+	} else {
+		cacheOn = true
+	}
+}
+
+// DisableCache turns off the package-level XPath compilation cache and
+// drops anything already cached. Every Find/FindOne/FindEach/Query call
+// will compile expr from scratch until the cache is re-enabled via
+// SetCacheSize.
+func DisableCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheOn = false
+	exprCache = make(map[string]*xpath.Expr)
+}
+
+// getQuery compiles expr, reusing a cached *xpath.Expr when the package
+// cache is enabled and already holds one for expr.
+func getQuery(expr string) (*xpath.Expr, error) {
+	cacheMu.RLock()
+	on := cacheOn
+	if on {
+		if exp, ok := exprCache[expr]; ok {
+			cacheMu.RUnlock()
+			return exp, nil
+		}
+	}
+	cacheMu.RUnlock()
+
+	exp, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if on {
+		cacheMu.Lock()
+		if len(exprCache) >= cacheSize {
+			exprCache = make(map[string]*xpath.Expr)
+		}
+		exprCache[expr] = exp
+		cacheMu.Unlock()
+	}
+	return exp, nil
+}
+
+// Iterator walks the html.Node matches of a compiled XPath expression one
+// at a time, without materializing the full result slice up front. It is
+// intended for large documents or expressions with many matches, where
+// Find's []*html.Node would otherwise hold everything in memory at once.
+type Iterator struct {
+	it *xpath.NodeIterator
+}
+
+// Next advances the Iterator to the next matching html.Node. It returns
+// false once there are no more matches.
+func (iter *Iterator) Next() (*html.Node, bool) {
+	if !iter.it.MoveNext() {
+		return nil, false
+	}
+	return (iter.it.Current().(*NodeNavigator)).curr, true
+}
+
+// Query compiles expr (reusing the package XPath cache) and returns an
+// Iterator over its matches against top.
+func Query(top *html.Node, expr string) (*Iterator, error) {
+	exp, err := getQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{it: exp.Select(CreateXPathNavigator(top))}, nil
+}
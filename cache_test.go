@@ -0,0 +1,44 @@
+package htmlquery
+
+import "testing"
+
+func TestQueryIterator(t *testing.T) {
+	iter, err := Query(testDoc, "//li")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c int
+	for {
+		_, ok := iter.Next()
+		if !ok {
+			break
+		}
+		c++
+	}
+	if c != 3 {
+		t.Fatalf("expected 3 matches, got %d", c)
+	}
+}
+
+func TestCacheSize(t *testing.T) {
+	defer SetCacheSize(defaultCacheSize)
+
+	SetCacheSize(1)
+	if _, err := Find(testDoc, "//li"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Find(testDoc, "//a"); err != nil {
+		t.Fatal(err)
+	}
+
+	DisableCache()
+	if _, err := Find(testDoc, "//li"); err != nil {
+		t.Fatal(err)
+	}
+	cacheMu.RLock()
+	n := len(exprCache)
+	cacheMu.RUnlock()
+	if n != 0 {
+		t.Fatalf("expected cache to stay empty while disabled, got %d entries", n)
+	}
+}
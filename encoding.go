@@ -0,0 +1,123 @@
+package htmlquery
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// LoadOptions controls how LoadURLWithOptions decodes a fetched response
+// body before parsing it as HTML.
+type LoadOptions struct {
+	// ForceEncoding, when set, names a charset (e.g. "windows-1251")
+	// used to decode the body in place of the Content-Type header and any
+	// <meta> declaration. A byte-order mark at the start of the body still
+	// takes precedence, and a name the charset package does not recognize
+	// falls back to sniffing the body.
+	ForceEncoding string
+
+	// DetectFromMeta sniffs a <meta charset> (or http-equiv
+	// Content-Type) declaration from the first KB of the body and uses
+	// it to decode, instead of trusting the response's Content-Type
+	// header. Ignored if ForceEncoding is set.
+	DetectFromMeta bool
+
+	// Raw skips charset conversion altogether and parses the body as-is.
+	// Ignored if ForceEncoding or DetectFromMeta is set.
+	Raw bool
+
+	// Loader performs the request. If nil, DefaultLoader is used.
+	Loader *Loader
+}
+
+// metaCharsetRe matches a <meta charset="..."> or a
+// <meta http-equiv="Content-Type" content="...; charset=..."> declaration.
+var metaCharsetRe = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?([\w-]+)`)
+
+// LoadURLWithOptions loads the HTML document from the specified URL,
+// giving control over how the response body's character encoding is
+// resolved instead of always trusting the Content-Type header.
+func LoadURLWithOptions(ctx context.Context, rawurl string, opts LoadOptions) (*html.Node, error) {
+	loader := opts.Loader
+	if loader == nil {
+		loader = DefaultLoader
+	}
+	res, err := loader.do(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if opts.ForceEncoding != "" {
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		return ParseBytes(data, opts.ForceEncoding)
+	}
+
+	if opts.DetectFromMeta {
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		return ParseBytes(data, detectMetaCharset(data))
+	}
+
+	if opts.Raw {
+		return html.Parse(res.Body)
+	}
+
+	r, err := charset.NewReader(res.Body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return html.Parse(r)
+}
+
+// ParseFromResponse decodes and parses res.Body as HTML according to its
+// Content-Type header, closing the body once done. It lets callers reuse
+// an *http.Response obtained through their own HTTP plumbing instead of
+// duplicating LoadURL's charset handling.
+func ParseFromResponse(res *http.Response) (*html.Node, error) {
+	defer res.Body.Close()
+	r, err := charset.NewReader(res.Body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return html.Parse(r)
+}
+
+// ParseBytes decodes data using encoding and parses the result as HTML.
+// An empty encoding lets charset.NewReader sniff it from data itself.
+func ParseBytes(data []byte, encoding string) (*html.Node, error) {
+	contentType := "text/html"
+	if encoding != "" {
+		contentType += "; charset=" + encoding
+	}
+	r, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, err
+	}
+	return html.Parse(r)
+}
+
+// detectMetaCharset scans the first KB of an HTML document for a
+// <meta charset> (or http-equiv Content-Type) declaration, returning ""
+// if none is found.
+func detectMetaCharset(data []byte) string {
+	n := len(data)
+	if n > 1024 {
+		n = 1024
+	}
+	m := metaCharsetRe.FindSubmatch(data[:n])
+	if m == nil {
+		return ""
+	}
+	return string(m[1])
+}
@@ -0,0 +1,78 @@
+package htmlquery
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoadURLWithOptionsDetectFromMeta(t *testing.T) {
+	body := `<html><head><meta charset="utf-8"></head><body>hello</body></html>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// No charset on the Content-Type header on purpose, forcing
+		// DetectFromMeta to find it in the body instead.
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	doc, err := LoadURLWithOptions(context.Background(), srv.URL, LoadOptions{DetectFromMeta: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, _ := FindOne(doc, "//body")
+	if InnerText(node) != "hello" {
+		t.Fatalf("unexpected body: %q", InnerText(node))
+	}
+}
+
+func TestLoadURLWithOptionsForceEncodingOverridesRaw(t *testing.T) {
+	// "café" in windows-1252: 'é' is the single byte 0xe9, not valid UTF-8.
+	body := []byte("<html><body>caf\xe9</body></html>")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	doc, err := LoadURLWithOptions(context.Background(), srv.URL, LoadOptions{
+		Raw:           true,
+		ForceEncoding: "windows-1252",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, _ := FindOne(doc, "//body")
+	if InnerText(node) != "café" {
+		t.Fatalf("ForceEncoding should take priority over Raw, got %q", InnerText(node))
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	doc, err := ParseBytes([]byte(`<html><body>hi</body></html>`), "utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, _ := FindOne(doc, "//body")
+	if InnerText(node) != "hi" {
+		t.Fatalf("unexpected body: %q", InnerText(node))
+	}
+}
+
+func TestParseFromResponse(t *testing.T) {
+	res := &http.Response{
+		Header: http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+		Body:   io.NopCloser(strings.NewReader(`<html><body>hey</body></html>`)),
+	}
+	doc, err := ParseFromResponse(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, _ := FindOne(doc, "//body")
+	if InnerText(node) != "hey" {
+		t.Fatalf("unexpected body: %q", InnerText(node))
+	}
+}
@@ -0,0 +1,246 @@
+// Package css compiles a small, practical subset of CSS Level 3 selectors
+// into equivalent XPath 1.0 expressions, so htmlquery's QuerySelector and
+// QuerySelectorAll can be layered on top of the existing xpath.Compile
+// path instead of adding a second query engine.
+//
+// Supported syntax: type selectors and *, #id, .class, [attr] and
+// [attr=val], the descendant/child/adjacent-sibling/general-sibling
+// combinators (space, >, +, ~), :first-child, :nth-child(n) and
+// :not(...). Selector groups (comma-separated selectors) are supported
+// and compile to an XPath union.
+package css
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// combinator identifies how a compound selector relates to the one
+// before it in a chain.
+type combinator byte
+
+const (
+	// none marks the first compound in a chain; there is nothing before it.
+	none       combinator = 0
+	descendant combinator = ' '
+	child      combinator = '>'
+	adjacent   combinator = '+'
+	sibling    combinator = '~'
+)
+
+// attrSelector is an [name] or [name=value] simple selector.
+type attrSelector struct {
+	name  string
+	value string
+	has   bool // true for [name=value], false for [name]
+}
+
+// pseudoSelector is a :name or :name(arg) simple selector.
+type pseudoSelector struct {
+	name string
+	arg  string
+}
+
+// compound is one "div.foo#bar[baz]" style run of simple selectors, plus
+// the combinator that connects it to the previous compound in the chain.
+type compound struct {
+	comb    combinator
+	tag     string // "" or "*" matches any element
+	id      string
+	classes []string
+	attrs   []attrSelector
+	pseudos []pseudoSelector
+}
+
+// Compile translates a CSS selector (optionally a comma-separated group
+// of selectors) into an XPath 1.0 expression selecting the same nodes,
+// evaluated relative to a context node.
+func Compile(sel string) (string, error) {
+	var parts []string
+	for _, group := range splitTopLevel(sel, ',') {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			return "", fmt.Errorf("css: empty selector in %q", sel)
+		}
+		chain, err := parseChain(group)
+		if err != nil {
+			return "", err
+		}
+		xp, err := chain.xpath()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, xp)
+	}
+	return strings.Join(parts, " | "), nil
+}
+
+type chain []compound
+
+// step is one compound selector rendered to XPath, paired with the axis
+// prefix that reaches it from the previous step.
+type step struct {
+	prefix string
+	expr   string
+}
+
+// xpath renders a parsed selector chain as an XPath expression rooted at
+// the context node (".").
+//
+// A general-sibling compound is folded into a "preceding-sibling::"
+// predicate on the step it's attached to, rather than chained onto the
+// path with "/following-sibling::". Path-chaining that axis evaluates it
+// once per node matched by the earlier compound, and antchfx/xpath does
+// not distinct-ify the resulting union: with three or more matching
+// siblings, later ones get selected from multiple earlier contexts and
+// come back duplicated. Folding the pair into a single step selects each
+// node exactly once.
+func (c chain) xpath() (string, error) {
+	var steps []step
+	for i, cp := range c {
+		expr, err := cp.xpath()
+		if err != nil {
+			return "", err
+		}
+		if cp.comb == sibling {
+			prev := steps[len(steps)-1]
+			steps[len(steps)-1] = step{
+				prefix: prev.prefix,
+				expr:   withPredicate(expr, "preceding-sibling::"+prev.expr),
+			}
+			continue
+		}
+		var prefix string
+		switch {
+		case i == 0:
+			prefix = ".//"
+		case cp.comb == descendant:
+			prefix = "//"
+		case cp.comb == child:
+			prefix = "/"
+		case cp.comb == adjacent:
+			prefix = "/following-sibling::*[1]/self::"
+		default:
+			return "", fmt.Errorf("css: unknown combinator %q", cp.comb)
+		}
+		steps = append(steps, step{prefix: prefix, expr: expr})
+	}
+
+	var buf strings.Builder
+	for _, s := range steps {
+		buf.WriteString(s.prefix)
+		buf.WriteString(s.expr)
+	}
+	return buf.String(), nil
+}
+
+// withPredicate adds pred to expr's existing bracketed predicate list, or
+// gives expr a new one if it doesn't have one yet.
+func withPredicate(expr, pred string) string {
+	if i := strings.IndexByte(expr, '['); i >= 0 {
+		return expr[:i+1] + pred + " and " + expr[i+1:]
+	}
+	return expr + "[" + pred + "]"
+}
+
+// xpath renders a single compound selector as an XPath step, e.g.
+// "div[contains(concat(' ',normalize-space(@class),' '),' foo ')]".
+func (cp compound) xpath() (string, error) {
+	tag := cp.tag
+	if tag == "" {
+		tag = "*"
+	}
+	var preds []string
+	if cp.id != "" {
+		preds = append(preds, fmt.Sprintf("@id=%s", quote(cp.id)))
+	}
+	for _, class := range cp.classes {
+		preds = append(preds, fmt.Sprintf(
+			"contains(concat(' ',normalize-space(@class),' '),%s)", quote(" "+class+" ")))
+	}
+	for _, a := range cp.attrs {
+		if a.has {
+			preds = append(preds, fmt.Sprintf("@%s=%s", a.name, quote(a.value)))
+		} else {
+			preds = append(preds, "@"+a.name)
+		}
+	}
+	for _, p := range cp.pseudos {
+		pred, err := p.xpath()
+		if err != nil {
+			return "", err
+		}
+		preds = append(preds, pred)
+	}
+
+	if len(preds) == 0 {
+		return tag, nil
+	}
+	return tag + "[" + strings.Join(preds, " and ") + "]", nil
+}
+
+// xpath renders a pseudo-class as an XPath predicate.
+func (p pseudoSelector) xpath() (string, error) {
+	switch p.name {
+	case "first-child":
+		return "not(preceding-sibling::*)", nil
+	case "nth-child":
+		n, err := strconv.Atoi(strings.TrimSpace(p.arg))
+		if err != nil {
+			return "", fmt.Errorf("css: :nth-child(%s) is not a literal integer", p.arg)
+		}
+		return fmt.Sprintf("count(preceding-sibling::*)=%d", n-1), nil
+	case "not":
+		inner, err := parseCompound(strings.TrimSpace(p.arg))
+		if err != nil {
+			return "", err
+		}
+		inner.pseudos = nil // :not() itself is not recursively negated
+		xp, err := inner.xpath()
+		if err != nil {
+			return "", err
+		}
+		// De Morgan: not(self::tag and pred), not just not(pred) — a
+		// compound like p.foo requires both the tag and the predicate, so
+		// negating only the predicate would wrongly keep the tag test.
+		if i := strings.IndexByte(xp, '['); i >= 0 {
+			tag, pred := xp[:i], xp[i+1:len(xp)-1]
+			if tag == "*" {
+				// self::* holds for every element, so only the predicate
+				// needs negating.
+				return "not(" + pred + ")", nil
+			}
+			return "not(self::" + tag + " and " + pred + ")", nil
+		}
+		// No predicate part: xp is just a tag test (or "*"). Negate the tag
+		// itself instead of discarding it; not(self::*) already evaluates
+		// to false() for any element context, so no special case is needed.
+		return "not(self::" + xp + ")", nil
+	default:
+		return "", fmt.Errorf("css: unsupported pseudo-class :%s", p.name)
+	}
+}
+
+// quote renders s as an XPath 1.0 string literal. XPath 1.0 has no
+// escape mechanism inside string literals, so a value containing a
+// single quote can't be wrapped in single quotes (and one containing
+// both quote characters can't be wrapped in either); concat() is the
+// only way to build such a literal.
+func quote(s string) string {
+	switch {
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'"
+	case !strings.Contains(s, `"`):
+		return `"` + s + `"`
+	}
+	parts := strings.Split(s, "'")
+	segments := make([]string, 0, len(parts)*2-1)
+	for i, part := range parts {
+		if i > 0 {
+			segments = append(segments, `"'"`)
+		}
+		segments = append(segments, "'"+part+"'")
+	}
+	return "concat(" + strings.Join(segments, ",") + ")"
+}
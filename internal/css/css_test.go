@@ -0,0 +1,64 @@
+package css
+
+import "testing"
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		sel  string
+		want string
+	}{
+		{"div", ".//div"},
+		{"*", ".//*"},
+		{"#main", ".//*[@id='main']"},
+		{".foo", ".//*[contains(concat(' ',normalize-space(@class),' '),' foo ')]"},
+		{"div.foo", ".//div[contains(concat(' ',normalize-space(@class),' '),' foo ')]"},
+		{"a[href]", ".//a[@href]"},
+		{"a[href=#]", ".//a[@href='#']"},
+		{"div.foo > a[href]", ".//div[contains(concat(' ',normalize-space(@class),' '),' foo ')]/a[@href]"},
+		{"ul li", ".//ul//li"},
+		{"li:first-child", ".//li[not(preceding-sibling::*)]"},
+		{"li:nth-child(2)", ".//li[count(preceding-sibling::*)=1]"},
+		{"li:not(.foo)", ".//li[not(contains(concat(' ',normalize-space(@class),' '),' foo '))]"},
+		{"li:not(p)", ".//li[not(self::p)]"},
+		{"li:not(*)", ".//li[not(self::*)]"},
+		{"li:not(p.foo)", ".//li[not(self::p and contains(concat(' ',normalize-space(@class),' '),' foo '))]"},
+		{"li ~ li", ".//li[preceding-sibling::li]"},
+		{"div > p ~ span", ".//div/span[preceding-sibling::p]"},
+		{`[data-x="it's"]`, `.//*[@data-x="it's"]`},
+		{`[data-x='it"s']`, `.//*[@data-x='it"s']`},
+	}
+	for _, tt := range tests {
+		got, err := Compile(tt.sel)
+		if err != nil {
+			t.Errorf("Compile(%q) returned error: %v", tt.sel, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Compile(%q) = %q, want %q", tt.sel, got, tt.want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"foo", "'foo'"},
+		{"it's", `"it's"`},
+		{`it's a "test"`, `concat('it',"'",'s a "test"')`},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.in); got != tt.want {
+			t.Errorf("quote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, sel := range []string{"", "li:nth-child(n)", "["} {
+		if _, err := Compile(sel); err == nil {
+			t.Errorf("Compile(%q) expected an error", sel)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package css
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitTopLevel splits s on sep, ignoring occurrences of sep inside
+// [...] or (...) so that e.g. "a[href=','], b" splits into two groups.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		default:
+			if s[i] == sep && depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	return parts
+}
+
+// parseChain parses a single (non-grouped) selector such as
+// "div.foo > a[href]" into a chain of compound selectors.
+func parseChain(s string) (chain, error) {
+	var c chain
+	comb := none
+	i := 0
+	for i < len(s) {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if b := s[i]; b == '>' || b == '+' || b == '~' {
+			comb = combinator(b)
+			i++
+			for i < len(s) && s[i] == ' ' {
+				i++
+			}
+			continue
+		}
+
+		j := nextCombinatorIndex(s, i)
+		cp, err := parseCompound(strings.TrimSpace(s[i:j]))
+		if err != nil {
+			return nil, err
+		}
+		if len(c) == 0 {
+			cp.comb = none
+		} else {
+			cp.comb = comb
+		}
+		c = append(c, cp)
+		comb = descendant
+		i = j
+	}
+	if len(c) == 0 {
+		return nil, fmt.Errorf("css: empty selector")
+	}
+	return c, nil
+}
+
+// nextCombinatorIndex returns the index in s, starting at i, of the next
+// unbracketed space, '>', '+' or '~' that separates compound selectors,
+// or len(s) if there is none.
+func nextCombinatorIndex(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ' ', '>', '+', '~':
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(s)
+}
+
+// parseCompound parses one "tag#id.class[attr]:pseudo" run with no
+// combinators in it.
+func parseCompound(s string) (compound, error) {
+	var cp compound
+	i := 0
+	for i < len(s) {
+		switch s[i] {
+		case '#':
+			j := scanIdent(s, i+1)
+			cp.id = s[i+1 : j]
+			i = j
+		case '.':
+			j := scanIdent(s, i+1)
+			cp.classes = append(cp.classes, s[i+1:j])
+			i = j
+		case '[':
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				return compound{}, fmt.Errorf("css: unterminated [ in %q", s)
+			}
+			j += i
+			attr, err := parseAttr(s[i+1 : j])
+			if err != nil {
+				return compound{}, err
+			}
+			cp.attrs = append(cp.attrs, attr)
+			i = j + 1
+		case ':':
+			j := scanIdent(s, i+1)
+			p := pseudoSelector{name: s[i+1 : j]}
+			if j < len(s) && s[j] == '(' {
+				k := strings.IndexByte(s[j:], ')')
+				if k < 0 {
+					return compound{}, fmt.Errorf("css: unterminated ( in %q", s)
+				}
+				k += j
+				p.arg = s[j+1 : k]
+				j = k + 1
+			}
+			cp.pseudos = append(cp.pseudos, p)
+			i = j
+		case '*':
+			cp.tag = "*"
+			i++
+		default:
+			j := scanIdent(s, i)
+			if j == i {
+				return compound{}, fmt.Errorf("css: unexpected character %q in %q", s[i], s)
+			}
+			cp.tag = s[i:j]
+			i = j
+		}
+	}
+	return cp, nil
+}
+
+// scanIdent returns the index of the end of the identifier (letters,
+// digits, '-' and '_') starting at i, or i itself if there is none.
+func scanIdent(s string, i int) int {
+	j := i
+	for j < len(s) {
+		c := s[j]
+		if !(c == '-' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			break
+		}
+		j++
+	}
+	return j
+}
+
+// parseAttr parses the contents of an [attr] or [attr=value] selector
+// (without the surrounding brackets).
+func parseAttr(s string) (attrSelector, error) {
+	eq := strings.IndexByte(s, '=')
+	if eq < 0 {
+		return attrSelector{name: strings.TrimSpace(s)}, nil
+	}
+	name := strings.TrimSpace(s[:eq])
+	val := strings.TrimSpace(s[eq+1:])
+	val = strings.Trim(val, `"'`)
+	return attrSelector{name: name, value: val, has: true}, nil
+}
@@ -0,0 +1,250 @@
+package htmlquery
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// LoadResult is the outcome of loading a single URL through
+// Loader.LoadAll.
+type LoadResult struct {
+	URL  string
+	Node *html.Node
+	Err  error
+}
+
+// Loader loads HTML documents over HTTP with optional retries, backoff
+// and rate limiting. The zero value is ready to use and behaves like a
+// plain http.DefaultClient GET with no retries and no rate limit.
+type Loader struct {
+	// Client is used to perform requests. If nil, http.DefaultClient is
+	// used, or a client dialing through Proxy if Proxy is set.
+	Client *http.Client
+
+	// MaxRetries is the number of additional attempts made after a
+	// failed request. Zero means no retries.
+	MaxRetries int
+
+	// Backoff returns how long to wait before retry attempt n (1-based).
+	// If nil, DefaultBackoff is used.
+	Backoff func(attempt int) time.Duration
+
+	// RateLimit caps the number of requests issued per second by this
+	// Loader, across both Load and LoadAll. Zero (the default) means
+	// unlimited.
+	RateLimit float64
+
+	// UserAgent, when set, is sent as the User-Agent header on every
+	// request.
+	UserAgent string
+
+	// Headers are set on every request.
+	Headers map[string]string
+
+	// Proxy, when set and Client is nil, is used as the proxy URL for
+	// every request (e.g. "http://proxyIp:proxyPort").
+	Proxy string
+
+	clientOnce  sync.Once
+	client      *http.Client
+	limiterOnce sync.Once
+	limiter     *rateLimiter
+}
+
+// DefaultLoader is the Loader used by LoadURL.
+var DefaultLoader = &Loader{}
+
+// DefaultBackoff waits 2^attempt*100ms plus up to 100ms of jitter.
+func DefaultBackoff(attempt int) time.Duration {
+	base := time.Duration(1<<uint(attempt)) * 100 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(100*time.Millisecond)))
+}
+
+// Load fetches url and parses it into an html.Node, retrying on network
+// errors and 5xx/429 responses according to MaxRetries and Backoff.
+func (l *Loader) Load(ctx context.Context, rawurl string) (*html.Node, error) {
+	res, err := l.do(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	r, err := charset.NewReader(res.Body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return html.Parse(r)
+}
+
+// LoadAll loads urls concurrently, respecting RateLimit, and streams one
+// LoadResult per URL to the returned channel as it completes. The
+// channel is closed once every URL has been processed.
+func (l *Loader) LoadAll(ctx context.Context, urls []string) <-chan LoadResult {
+	results := make(chan LoadResult)
+	go func() {
+		defer close(results)
+		var wg sync.WaitGroup
+		for _, u := range urls {
+			wg.Add(1)
+			go func(u string) {
+				defer wg.Done()
+				node, err := l.Load(ctx, u)
+				results <- LoadResult{URL: u, Node: node, Err: err}
+			}(u)
+		}
+		wg.Wait()
+	}()
+	return results
+}
+
+func (l *Loader) do(ctx context.Context, rawurl string) (*http.Response, error) {
+	client := l.httpClient()
+	attempts := l.MaxRetries + 1
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if lim := l.rateLimiter(); lim != nil {
+			if err := lim.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequest(http.MethodGet, rawurl, nil)
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		if l.UserAgent != "" {
+			req.Header.Set("User-Agent", l.UserAgent)
+		}
+		for k, v := range l.Headers {
+			req.Header.Set(k, v)
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			if !l.retryAfterWait(ctx, attempt, attempts, 0) {
+				return nil, lastErr
+			}
+			continue
+		}
+
+		if res.StatusCode < http.StatusInternalServerError && res.StatusCode != http.StatusTooManyRequests {
+			return res, nil
+		}
+
+		lastErr = fmt.Errorf("htmlquery: %s: unexpected status %d", rawurl, res.StatusCode)
+		wait := parseRetryAfter(res.Header.Get("Retry-After"))
+		res.Body.Close()
+		if !l.retryAfterWait(ctx, attempt, attempts, wait) {
+			return nil, lastErr
+		}
+	}
+	return nil, lastErr
+}
+
+// retryAfterWait sleeps before the next attempt and reports whether one
+// remains. wait, if positive, overrides the computed backoff (used to
+// honor a Retry-After header).
+func (l *Loader) retryAfterWait(ctx context.Context, attempt, attempts int, wait time.Duration) bool {
+	if attempt >= attempts-1 {
+		return false
+	}
+	if wait <= 0 {
+		if l.Backoff != nil {
+			wait = l.Backoff(attempt + 1)
+		} else {
+			wait = DefaultBackoff(attempt + 1)
+		}
+	}
+	select {
+	case <-time.After(wait):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *Loader) httpClient() *http.Client {
+	l.clientOnce.Do(func() {
+		switch {
+		case l.Client != nil:
+			l.client = l.Client
+		case l.Proxy != "":
+			proxyURL, err := url.Parse(l.Proxy)
+			if err != nil {
+				l.client = http.DefaultClient
+				return
+			}
+			l.client = &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+		default:
+			l.client = http.DefaultClient
+		}
+	})
+	return l.client
+}
+
+func (l *Loader) rateLimiter() *rateLimiter {
+	l.limiterOnce.Do(func() {
+		if l.RateLimit > 0 {
+			l.limiter = newRateLimiter(l.RateLimit)
+		}
+	})
+	return l.limiter
+}
+
+// parseRetryAfter parses a Retry-After header value expressed either as
+// a number of seconds or an HTTP-date, returning 0 if v is empty or
+// unparseable.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// rateLimiter enforces a minimum interval between successive calls to
+// wait, giving Loader a simple requests/sec throttle without pulling in
+// an external dependency.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (r *rateLimiter) wait(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if d := r.interval - time.Since(r.last); d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	r.last = time.Now()
+	return nil
+}
@@ -0,0 +1,74 @@
+package htmlquery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoaderRetries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer srv.Close()
+
+	loader := &Loader{
+		MaxRetries: 2,
+		Backoff:    func(attempt int) time.Duration { return time.Millisecond },
+	}
+	doc, err := loader.Load(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	node, _ := FindOne(doc, "//body")
+	if InnerText(node) != "ok" {
+		t.Fatalf("unexpected body: %q", InnerText(node))
+	}
+}
+
+func TestLoaderGivesUpAfterMaxRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	loader := &Loader{
+		MaxRetries: 1,
+		Backoff:    func(attempt int) time.Duration { return time.Millisecond },
+	}
+	if _, err := loader.Load(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>ok</body></html>`))
+	}))
+	defer srv.Close()
+
+	loader := &Loader{}
+	results := loader.LoadAll(context.Background(), []string{srv.URL, srv.URL})
+
+	var n int
+	for res := range results {
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 results, got %d", n)
+	}
+}
@@ -8,12 +8,9 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
-	"net/url"
 
 	"github.com/antchfx/xpath"
 	"golang.org/x/net/html"
-	"golang.org/x/net/html/charset"
 )
 
 var _ xpath.NodeNavigator = &NodeNavigator{}
@@ -26,7 +23,7 @@ func CreateXPathNavigator(top *html.Node) *NodeNavigator {
 // Find searches the html.Node that matches by the specified XPath expr.
 func Find(top *html.Node, expr string) ([]*html.Node, error) {
 	var elems []*html.Node
-	exp, err := xpath.Compile(expr)
+	exp, err := getQuery(expr)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +38,7 @@ func Find(top *html.Node, expr string) ([]*html.Node, error) {
 // and returns first element of matched html.Node.
 func FindOne(top *html.Node, expr string) (*html.Node, error) {
 	var elem *html.Node
-	exp, err := xpath.Compile(expr)
+	exp, err := getQuery(expr)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +51,7 @@ func FindOne(top *html.Node, expr string) (*html.Node, error) {
 
 // FindEach searches the html.Node and calls functions cb.
 func FindEach(top *html.Node, expr string, cb func(int, *html.Node)) error {
-	exp, err := xpath.Compile(expr)
+	exp, err := getQuery(expr)
 	if err != nil {
 		return err
 	}
@@ -69,83 +66,17 @@ func FindEach(top *html.Node, expr string, cb func(int, *html.Node)) error {
 
 // LoadURL loads the HTML document from the specified URL.
 func LoadURL(ctx context.Context, url string) (*html.Node, error) {
-	Ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req = req.WithContext(Ctx)
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	r, err := charset.NewReader(res.Body, res.Header.Get("Content-Type"))
-	if err != nil {
-		return nil, err
-	}
-	return html.Parse(r)
+	return DefaultLoader.Load(ctx, url)
 }
 
-//LoadURLWithHeader loads the HTML document from the specified URL with http header
+// LoadURLWithHeader loads the HTML document from the specified URL with http header
 func LoadURLWithHeader(ctx context.Context, link string, headers map[string]string) (*html.Node, error) {
-	Ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	client := &http.Client{}
-	request, err := http.NewRequest("GET", link, nil)
-	for k, v := range headers {
-		request.Header.Set(k, v)
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	request = request.WithContext(Ctx)
-	resp, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	r, err := charset.NewReader(resp.Body, resp.Header.Get("Content-Type"))
-	if err != nil {
-		return nil, err
-	}
-	return html.Parse(r)
+	return (&Loader{Headers: headers}).Load(ctx, link)
 }
 
 // LoadURLWithProxy loads the HTML document from the specified URL with Proxy.
 func LoadURLWithProxy(ctx context.Context, link string, proxy string) (*html.Node, error) {
-	Ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	proxyUrl, err := url.Parse(proxy) //proxy = http://proxyIp:proxyPort
-	Client := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		},
-	}
-
-	req, err := http.NewRequest(http.MethodGet, link, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req = req.WithContext(Ctx)
-	res, err := Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
-	r, err := charset.NewReader(res.Body, res.Header.Get("Content-Type"))
-	if err != nil {
-		return nil, err
-	}
-	return html.Parse(r)
+	return (&Loader{Proxy: proxy}).Load(ctx, link)
 }
 
 // Parse returns the parse tree for the HTML from the given Reader.
@@ -0,0 +1,62 @@
+package htmlquery
+
+import (
+	"sync"
+
+	"golang.org/x/net/html"
+
+	"github.com/antchfx/htmlquery/internal/css"
+)
+
+// selectorCacheMu and selectorCache hold CSS-selector-to-XPath
+// translations, separate from the *xpath.Expr cache in cache.go since
+// the compiled result here is a string, not an *xpath.Expr.
+var (
+	selectorCacheMu sync.RWMutex
+	selectorCache   = make(map[string]string)
+)
+
+// toXPath translates a CSS selector to XPath, caching the translation so
+// repeated QuerySelector/QuerySelectorAll calls with the same selector
+// skip re-parsing it.
+func toXPath(sel string) (string, error) {
+	selectorCacheMu.RLock()
+	if xp, ok := selectorCache[sel]; ok {
+		selectorCacheMu.RUnlock()
+		return xp, nil
+	}
+	selectorCacheMu.RUnlock()
+
+	xp, err := css.Compile(sel)
+	if err != nil {
+		return "", err
+	}
+
+	selectorCacheMu.Lock()
+	if len(selectorCache) >= defaultCacheSize {
+		selectorCache = make(map[string]string)
+	}
+	selectorCache[sel] = xp
+	selectorCacheMu.Unlock()
+	return xp, nil
+}
+
+// QuerySelector searches the html.Node that matches the specified CSS
+// selector, in the same way FindOne does for an XPath expression.
+func QuerySelector(top *html.Node, sel string) (*html.Node, error) {
+	expr, err := toXPath(sel)
+	if err != nil {
+		return nil, err
+	}
+	return FindOne(top, expr)
+}
+
+// QuerySelectorAll searches the html.Node that matches the specified CSS
+// selector, in the same way Find does for an XPath expression.
+func QuerySelectorAll(top *html.Node, sel string) ([]*html.Node, error) {
+	expr, err := toXPath(sel)
+	if err != nil {
+		return nil, err
+	}
+	return Find(top, expr)
+}
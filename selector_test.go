@@ -0,0 +1,60 @@
+package htmlquery
+
+import (
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestQuerySelector(t *testing.T) {
+	node, err := QuerySelector(testDoc, "div.container > article h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node == nil || InnerText(node) != "London" {
+		t.Fatalf("expected article h1 'London', got %v", node)
+	}
+}
+
+func TestQuerySelectorAll(t *testing.T) {
+	nodes, err := QuerySelectorAll(testDoc, "nav li")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 li nodes, got %d", len(nodes))
+	}
+}
+
+func TestQuerySelectorAllGeneralSibling(t *testing.T) {
+	// nav ul has 3 li's; "li ~ li" should match the last two, each once,
+	// not the third li duplicated via two distinct preceding siblings.
+	nodes, err := QuerySelectorAll(testDoc, "nav li ~ li")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 li nodes, got %d", len(nodes))
+	}
+	seen := make(map[*html.Node]bool)
+	for _, n := range nodes {
+		if seen[n] {
+			t.Fatalf("node %v returned more than once", n)
+		}
+		seen[n] = true
+	}
+	if InnerText(nodes[0]) != "Paris" || InnerText(nodes[1]) != "Tokyo" {
+		t.Fatalf("expected [Paris Tokyo], got [%s %s]", InnerText(nodes[0]), InnerText(nodes[1]))
+	}
+}
+
+func TestQuerySelectorAttrApostrophe(t *testing.T) {
+	doc, _ := loadHTML(`<div data-x="it's complicated"></div>`)
+	node, err := QuerySelector(doc, `[data-x="it's complicated"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node == nil {
+		t.Fatal("expected a match for an attribute value containing an apostrophe")
+	}
+}
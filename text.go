@@ -0,0 +1,292 @@
+package htmlquery
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// normalizedBlockTags are the elements NormalizedText surrounds with
+// newlines, mirroring the tags a browser lays out as blocks.
+var normalizedBlockTags = map[string]bool{
+	"p": true, "div": true, "li": true, "br": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// nonContentTags hold non-visible document machinery (scripts, styles)
+// rather than renderable text; NormalizedText and Markdown skip their
+// subtrees entirely instead of leaking raw source into extracted text.
+var nonContentTags = map[string]bool{
+	"script": true, "style": true, "noscript": true,
+}
+
+var whitespaceRe = regexp.MustCompile(`\s+`)
+
+var (
+	leadingTrailingNewlineSpaceRe = regexp.MustCompile(` *\n *`)
+	repeatedNewlineRe             = regexp.MustCompile(`\n{2,}`)
+	repeatedSpaceRe               = regexp.MustCompile(` {2,}`)
+	collapseBlankLinesRe          = regexp.MustCompile(`\n{3,}`)
+)
+
+// NormalizedText returns the visible text of n's subtree closer to how a
+// browser renders it than InnerText does: runs of whitespace collapse to
+// a single space, adjacent inline elements get a separating space, and
+// block-level tags (p, div, li, br, h1-h6, tr) are surrounded by
+// newlines instead of running into their neighbors.
+func NormalizedText(n *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			buf.WriteString(whitespaceRe.ReplaceAllString(n.Data, " "))
+			return
+		case html.CommentNode:
+			return
+		}
+		if n.Type == html.ElementNode && nonContentTags[n.Data] {
+			return
+		}
+
+		block := n.Type == html.ElementNode && normalizedBlockTags[n.Data]
+		if block {
+			buf.WriteByte('\n')
+		} else if n.Type == html.ElementNode && needsBoundarySpace(&buf) {
+			buf.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			buf.WriteByte('\n')
+		}
+	}
+	walk(n)
+
+	out := buf.String()
+	out = leadingTrailingNewlineSpaceRe.ReplaceAllString(out, "\n")
+	out = repeatedNewlineRe.ReplaceAllString(out, "\n")
+	out = repeatedSpaceRe.ReplaceAllString(out, " ")
+	return strings.TrimSpace(out)
+}
+
+// needsBoundarySpace reports whether buf's last written byte needs a
+// separating space before the next inline element starts.
+func needsBoundarySpace(buf *bytes.Buffer) bool {
+	if buf.Len() == 0 {
+		return false
+	}
+	last := buf.Bytes()[buf.Len()-1]
+	return last != ' ' && last != '\n'
+}
+
+// Markdown renders n's subtree as CommonMark, supporting headings,
+// paragraphs, ordered and unordered lists, links, images, inline and
+// fenced code, blockquotes and tables. It is a pure function over
+// *html.Node, useful for turning arbitrary scraped fragments into clean
+// text bodies.
+func Markdown(n *html.Node) string {
+	var buf bytes.Buffer
+	renderMarkdownBlocks(&buf, n, 0)
+	out := collapseBlankLinesRe.ReplaceAllString(buf.String(), "\n\n")
+	return strings.TrimSpace(out)
+}
+
+func renderMarkdownBlocks(buf *bytes.Buffer, n *html.Node, listDepth int) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderMarkdownBlock(buf, c, listDepth)
+	}
+}
+
+func renderMarkdownBlock(buf *bytes.Buffer, n *html.Node, listDepth int) {
+	switch n.Type {
+	case html.CommentNode:
+		return
+	case html.TextNode:
+		if text := strings.TrimSpace(whitespaceRe.ReplaceAllString(n.Data, " ")); text != "" {
+			buf.WriteString(text)
+			buf.WriteString("\n\n")
+		}
+		return
+	case html.DocumentNode:
+		renderMarkdownBlocks(buf, n, listDepth)
+		return
+	case html.ElementNode:
+		// fall through
+	default:
+		return
+	}
+	if nonContentTags[n.Data] {
+		return
+	}
+
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		buf.WriteString(strings.Repeat("#", int(n.Data[1]-'0')))
+		buf.WriteString(" ")
+		buf.WriteString(renderMarkdownInline(n))
+		buf.WriteString("\n\n")
+	case "p":
+		buf.WriteString(renderMarkdownInline(n))
+		buf.WriteString("\n\n")
+	case "blockquote":
+		var inner bytes.Buffer
+		renderMarkdownBlocks(&inner, n, listDepth)
+		text := strings.TrimRight(inner.String(), "\n")
+		for _, line := range strings.Split(text, "\n") {
+			buf.WriteString("> ")
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	case "pre":
+		buf.WriteString("```\n")
+		buf.WriteString(strings.TrimRight(InnerText(n), "\n"))
+		buf.WriteString("\n```\n\n")
+	case "ul", "ol":
+		renderMarkdownList(buf, n, listDepth, n.Data == "ol")
+	case "table":
+		renderMarkdownTable(buf, n)
+	default:
+		renderMarkdownBlocks(buf, n, listDepth)
+	}
+}
+
+func renderMarkdownList(buf *bytes.Buffer, n *html.Node, listDepth int, ordered bool) {
+	i := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			continue
+		}
+		marker := "-"
+		if ordered {
+			marker = fmt.Sprintf("%d.", i)
+			i++
+		}
+		buf.WriteString(strings.Repeat("  ", listDepth))
+		buf.WriteString(marker)
+		buf.WriteString(" ")
+		buf.WriteString(renderMarkdownInline(c))
+		buf.WriteString("\n")
+
+		for gc := c.FirstChild; gc != nil; gc = gc.NextSibling {
+			if gc.Type == html.ElementNode && (gc.Data == "ul" || gc.Data == "ol") {
+				renderMarkdownList(buf, gc, listDepth+1, gc.Data == "ol")
+			}
+		}
+	}
+	if listDepth == 0 {
+		buf.WriteString("\n")
+	}
+}
+
+func renderMarkdownTable(buf *bytes.Buffer, n *html.Node) {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "tr" {
+			var cells []string
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && (c.Data == "td" || c.Data == "th") {
+					cells = append(cells, renderMarkdownInline(c))
+				}
+			}
+			rows = append(rows, cells)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return
+	}
+
+	writeRow := func(cells []string) {
+		buf.WriteString("|")
+		for _, c := range cells {
+			buf.WriteString(" ")
+			buf.WriteString(c)
+			buf.WriteString(" |")
+		}
+		buf.WriteString("\n")
+	}
+
+	writeRow(rows[0])
+	buf.WriteString("|")
+	for range rows[0] {
+		buf.WriteString(" --- |")
+	}
+	buf.WriteString("\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	buf.WriteString("\n")
+}
+
+// renderMarkdownInline renders n's subtree as inline CommonMark, for use
+// within a block context such as a paragraph, heading or table cell.
+func renderMarkdownInline(n *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(*html.Node)
+	walkChildren := func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			buf.WriteString(whitespaceRe.ReplaceAllString(n.Data, " "))
+			return
+		case html.CommentNode:
+			return
+		case html.ElementNode:
+			// fall through
+		default:
+			walkChildren(n)
+			return
+		}
+		if nonContentTags[n.Data] {
+			return
+		}
+
+		switch n.Data {
+		case "br":
+			buf.WriteString("  \n")
+		case "strong", "b":
+			buf.WriteString("**")
+			walkChildren(n)
+			buf.WriteString("**")
+		case "em", "i":
+			buf.WriteString("_")
+			walkChildren(n)
+			buf.WriteString("_")
+		case "code":
+			buf.WriteString("`")
+			buf.WriteString(InnerText(n))
+			buf.WriteString("`")
+		case "a":
+			buf.WriteString("[")
+			walkChildren(n)
+			buf.WriteString("](")
+			buf.WriteString(SelectAttr(n, "href"))
+			buf.WriteString(")")
+		case "img":
+			buf.WriteString("![")
+			buf.WriteString(SelectAttr(n, "alt"))
+			buf.WriteString("](")
+			buf.WriteString(SelectAttr(n, "src"))
+			buf.WriteString(")")
+		default:
+			walkChildren(n)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,67 @@
+package htmlquery
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizedText(t *testing.T) {
+	doc, _ := loadHTML(`<div>  Hello   <b>world</b>
+	<p>Second   line</p><ul><li>One</li><li>Two</li></ul></div>`)
+	got := NormalizedText(doc)
+	want := "Hello world\nSecond line\nOne\nTwo"
+	if got != want {
+		t.Fatalf("NormalizedText() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	doc, _ := loadHTML(`<div>
+<h1>Title</h1>
+<p>Some <b>bold</b> and <a href="https://example.com">a link</a>.</p>
+<ul><li>one</li><li>two</li></ul>
+<blockquote><p>quoted</p></blockquote>
+<pre>code line</pre>
+</div>`)
+	got := Markdown(doc)
+
+	for _, want := range []string{
+		"# Title",
+		"Some **bold** and [a link](https://example.com).",
+		"- one",
+		"- two",
+		"> quoted",
+		"```\ncode line\n```",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Markdown() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestNormalizedTextSkipsScriptAndStyle(t *testing.T) {
+	doc, _ := loadHTML(`<div><p>Hello</p><script>var x = "evil()";</script><style>p{color:red}</style></div>`)
+	got := NormalizedText(doc)
+	want := "Hello"
+	if got != want {
+		t.Fatalf("NormalizedText() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownSkipsScriptAndStyle(t *testing.T) {
+	doc, _ := loadHTML(`<div><p>Hello</p><script>var x = "evil()";</script><style>p{color:red}</style></div>`)
+	got := Markdown(doc)
+	want := "Hello"
+	if got != want {
+		t.Fatalf("Markdown() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	doc, _ := loadHTML(`<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>`)
+	got := Markdown(doc)
+	want := "| A | B |\n| --- | --- |\n| 1 | 2 |"
+	if !strings.Contains(got, want) {
+		t.Fatalf("Markdown() = %q, missing table %q", got, want)
+	}
+}
@@ -0,0 +1,208 @@
+package htmlquery
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Unmarshal populates v, a pointer to a struct, from node using the
+// XPath expressions declared in its "xpath" struct tags, turning the
+// common FindEach+SelectAttr+InnerText pattern into a single declarative
+// call:
+//
+//	type Page struct {
+//		Title string   `xpath:"//title"`
+//		Links []string `xpath:"//a/@href"`
+//	}
+//
+// A struct-typed field evaluates its own tags relative to the match of
+// its parent's xpath tag, and a slice-of-structs field produces one
+// element per match of its xpath tag. Supported scalar field types are
+// string, the integer and float kinds, bool, and time.Time (parsed with
+// the "format" tag, defaulting to time.RFC3339). An "attr" tag is a
+// shorthand for extracting an attribute of the node the enclosing struct
+// is bound to, equivalent to an xpath tag of "@name".
+func Unmarshal(node *html.Node, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("htmlquery: Unmarshal requires a non-nil pointer to a struct, got %T", v)
+	}
+	return unmarshalStruct(node, rv.Elem())
+}
+
+func unmarshalStruct(node *html.Node, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		if err := unmarshalField(node, rv.Field(i), sf); err != nil {
+			return fmt.Errorf("htmlquery: field %s: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalField(node *html.Node, fv reflect.Value, sf reflect.StructField) error {
+	expr, hasXPath := sf.Tag.Lookup("xpath")
+	attrName, hasAttr := sf.Tag.Lookup("attr")
+	if !hasXPath && !hasAttr {
+		return nil
+	}
+	format := sf.Tag.Get("format")
+
+	if hasAttr && !hasXPath {
+		return setScalar(fv, SelectAttr(node, attrName), format)
+	}
+
+	if fv.Kind() == reflect.Slice && isStructElem(fv.Type().Elem()) {
+		matches, err := Find(node, expr)
+		if err != nil {
+			return err
+		}
+		elemType := fv.Type().Elem()
+		slice := reflect.MakeSlice(fv.Type(), 0, len(matches))
+		for _, m := range matches {
+			ev := reflect.New(elemType).Elem()
+			if err := unmarshalStruct(m, ev); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, ev)
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	if fv.Kind() == reflect.Slice {
+		navs, err := selectNodes(node, expr)
+		if err != nil {
+			return err
+		}
+		elemType := fv.Type().Elem()
+		slice := reflect.MakeSlice(fv.Type(), 0, len(navs))
+		for _, nav := range navs {
+			ev := reflect.New(elemType).Elem()
+			if err := setScalar(ev, navText(nav), format); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, ev)
+		}
+		fv.Set(slice)
+		return nil
+	}
+
+	if isStructElem(fv.Type()) {
+		m, err := FindOne(node, expr)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return nil
+		}
+		return unmarshalStruct(m, fv)
+	}
+
+	navs, err := selectNodes(node, expr)
+	if err != nil {
+		return err
+	}
+	if len(navs) == 0 {
+		return nil
+	}
+	return setScalar(fv, navText(navs[0]), format)
+}
+
+// isStructElem reports whether t should be treated as a nested struct to
+// recurse into, as opposed to a scalar (time.Time is a struct but is a
+// supported scalar type).
+func isStructElem(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// selectNodes evaluates expr against node and returns the resulting
+// matches as NodeNavigator snapshots, preserving whether each match is
+// an attribute (so its value comes from Value()) or an element (whose
+// value/subtree lives on curr).
+func selectNodes(node *html.Node, expr string) ([]*NodeNavigator, error) {
+	exp, err := getQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	t := exp.Select(CreateXPathNavigator(node))
+	var out []*NodeNavigator
+	for t.MoveNext() {
+		nav := *(t.Current().(*NodeNavigator))
+		out = append(out, &nav)
+	}
+	return out, nil
+}
+
+// navText returns the string value of a selected node: an attribute's
+// value, or an element/text node's InnerText.
+func navText(nav *NodeNavigator) string {
+	if nav.NodeType() == xpath.AttributeNode {
+		return nav.Value()
+	}
+	return InnerText(nav.curr)
+}
+
+// setScalar converts raw into fv, a string, integer, float, bool or
+// time.Time field.
+func setScalar(fv reflect.Value, raw string, format string) error {
+	raw = strings.TrimSpace(raw)
+
+	if fv.Type() == timeType {
+		if raw == "" {
+			return nil
+		}
+		layout := format
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if raw == "" {
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
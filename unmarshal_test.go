@@ -0,0 +1,60 @@
+package htmlquery
+
+import "testing"
+
+func TestUnmarshal(t *testing.T) {
+	type City struct {
+		Name string `xpath:"."`
+		Href string `xpath:"@href"`
+	}
+	type Page struct {
+		Title   string   `xpath:"//title"`
+		Cities  []City   `xpath:"//nav//a"`
+		Hrefs   []string `xpath:"//nav//a/@href"`
+		Missing string   `xpath:"//does-not-exist"`
+	}
+
+	var page Page
+	if err := Unmarshal(testDoc, &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Title != "Hello,World!" {
+		t.Fatalf("Title = %q", page.Title)
+	}
+	if len(page.Cities) != 3 || page.Cities[0].Name != "London" {
+		t.Fatalf("Cities = %+v", page.Cities)
+	}
+	if page.Cities[0].Href != "#" {
+		t.Fatalf("Cities[0].Href = %q", page.Cities[0].Href)
+	}
+	if len(page.Hrefs) != 3 || page.Hrefs[0] != "#" {
+		t.Fatalf("Hrefs = %+v", page.Hrefs)
+	}
+	if page.Missing != "" {
+		t.Fatalf("Missing = %q, want empty", page.Missing)
+	}
+}
+
+func TestUnmarshalAttrTag(t *testing.T) {
+	type Anchor struct {
+		Href string `attr:"href"`
+	}
+	node, err := FindOne(testDoc, "//nav//a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a Anchor
+	if err := Unmarshal(node, &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Href != "#" {
+		t.Fatalf("Href = %q", a.Href)
+	}
+}
+
+func TestUnmarshalNonPointer(t *testing.T) {
+	type Page struct{}
+	if err := Unmarshal(testDoc, Page{}); err == nil {
+		t.Fatal("expected an error for a non-pointer destination")
+	}
+}